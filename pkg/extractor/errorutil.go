@@ -0,0 +1,54 @@
+package extractor
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// HighlightBytePosition scans r looking for the line and column corresponding
+// to byte offset pos. It returns the 1-indexed line and column, along with a
+// short highlight window: the line before the offending one (if any), the
+// offending line itself, and a caret line pointing at col. This is modeled on
+// errorutil.HighlightBytePosition, used by several YAML/HCL tooling packages
+// to turn a raw byte offset into a compiler-style diagnostic.
+func HighlightBytePosition(r io.ReadSeeker, pos int64) (line, col int, highlight string) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, ""
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return 0, 0, ""
+	}
+
+	if pos < 0 {
+		pos = 0
+	}
+	if pos > int64(len(content)) {
+		pos = int64(len(content))
+	}
+
+	before := content[:pos]
+	line = 1 + bytes.Count(before, []byte{'\n'})
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	col = int(pos-int64(lineStart)) + 1
+
+	lines := strings.Split(string(content), "\n")
+	lineIdx := line - 1
+	if lineIdx < 0 || lineIdx >= len(lines) {
+		return line, col, ""
+	}
+
+	var window []string
+	if lineIdx > 0 {
+		window = append(window, lines[lineIdx-1])
+	}
+	window = append(window, lines[lineIdx])
+	caretCol := col - 1
+	if caretCol < 0 {
+		caretCol = 0
+	}
+	window = append(window, strings.Repeat(" ", caretCol)+"^")
+
+	return line, col, strings.Join(window, "\n")
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 
 	"gopkg.in/yaml.v3"
 	k8sSigsYaml "sigs.k8s.io/yaml"
@@ -17,12 +18,44 @@ type InvalidFile struct {
 	ValidationErrors []error `yaml:"errors" json:"errors" xml:"errors"`
 }
 
+// InvalidYamlError describes a single malformed document encountered while
+// decoding a YAML stream. Line, Column, and Snippet are best-effort: go-yaml
+// only ever reports a line number embedded in its error message, never a
+// column, so Column points at the offending line's first non-whitespace
+// byte rather than the parser's actual failure point. All three are left at
+// their zero value when even the line number can't be recovered. JSON and
+// JSON Lines syntax errors reuse this type; for those, Column is the
+// position encoding/json reported on Line.
 type InvalidYamlError struct {
 	ErrorMessage string
+	Line         int
+	Column       int
+	Snippet      string
+	DocIndex     int
 }
 
 func (e *InvalidYamlError) Error() string {
-	return fmt.Sprintf("yaml validation error: %s\n", e.ErrorMessage)
+	if e.Line == 0 {
+		return fmt.Sprintf("yaml validation error: %s\n", e.ErrorMessage)
+	}
+	return fmt.Sprintf("yaml validation error: %s\nline %d, column %d:\n%s\n", e.ErrorMessage, e.Line, e.Column, e.Snippet)
+}
+
+// yamlErrorLine extracts the document-relative line number go-yaml embeds in
+// its decode error messages (e.g. "yaml: line 3: did not find ..."), since
+// neither plain decode errors nor yaml.TypeError expose it as a field.
+var yamlErrorLineRegexp = regexp.MustCompile(`line (\d+):`)
+
+func yamlErrorLine(err error) (int, bool) {
+	matches := yamlErrorLineRegexp.FindStringSubmatch(err.Error())
+	if matches == nil {
+		return 0, false
+	}
+	var line int
+	if _, scanErr := fmt.Sscanf(matches[1], "%d", &line); scanErr != nil {
+		return 0, false
+	}
+	return line, true
 }
 
 type FileReader interface {
@@ -43,23 +76,25 @@ func ToAbsolutePath(path string) (string, error) {
 	return "", fmt.Errorf("failed parsing absolute path %s", path)
 }
 
+// ExtractConfigurationsFromYamlFile is a thin wrapper around
+// ExtractConfigurationsFromSource for the common case of a single local
+// file, preserving its original (*[]Configuration, absolutePath, *InvalidFile)
+// signature for existing callers.
 func ExtractConfigurationsFromYamlFile(path string) (*[]Configuration, string, *InvalidFile) {
 	absolutePath, err := ToAbsolutePath(path)
 	if err != nil {
 		return nil, "", &InvalidFile{Path: path, ValidationErrors: []error{&InvalidYamlError{ErrorMessage: err.Error()}}}
 	}
 
-	content, err := ReadFileContent(absolutePath)
+	fileConfigurations, invalidFiles, err := ExtractConfigurationsFromSource(NewFileSource(absolutePath))
 	if err != nil {
 		return nil, "", &InvalidFile{Path: absolutePath, ValidationErrors: []error{&InvalidYamlError{ErrorMessage: err.Error()}}}
 	}
-
-	configurations, err := ParseYaml(content)
-	if err != nil {
-		return nil, "", &InvalidFile{Path: absolutePath, ValidationErrors: []error{&InvalidYamlError{ErrorMessage: err.Error()}}}
+	if len(invalidFiles) > 0 {
+		return nil, "", &invalidFiles[0]
 	}
 
-	return configurations, absolutePath, nil
+	return &fileConfigurations[0].Configurations, absolutePath, nil
 }
 
 type Configuration struct {
@@ -69,6 +104,11 @@ type Configuration struct {
 	Annotations  map[string]interface{}
 	Payload      []byte
 	YamlNode     yaml.Node
+	// SourceTemplate holds the name Helm's renderer gives the template a
+	// Configuration was rendered from: the template path prefixed with the
+	// chart name, e.g. "mychart/templates/deployment.yaml". It's empty for
+	// configurations that didn't come from a Helm chart.
+	SourceTemplate string
 }
 
 type FileConfigurations struct {
@@ -85,40 +125,125 @@ func ParseYaml(content string) (*[]Configuration, error) {
 	}
 }
 
+// yamlDocumentSeparatorRegexp matches a YAML document-start marker line
+// ("---", optionally trailing whitespace/a comment), which is how manifest
+// files in the wild separate documents.
+var yamlDocumentSeparatorRegexp = regexp.MustCompile(`(?m)^---[ \t]*(?:#.*)?$`)
+
+type yamlDocument struct {
+	text   []byte
+	offset int64
+}
+
+// splitYamlDocuments splits content into its top-level YAML documents on
+// "---" markers, recording each document's starting byte offset in content.
+// Decoding documents independently (rather than one shared yaml.Decoder
+// over the whole stream) is what lets a decode failure be mapped back to an
+// absolute file position: yaml.Decoder buffers ahead of the document it's
+// currently yielding, so the underlying reader's position right before a
+// Decode call is not reliably that document's start.
+func splitYamlDocuments(content []byte) []yamlDocument {
+	starts := []int{0}
+	for _, separator := range yamlDocumentSeparatorRegexp.FindAllIndex(content, -1) {
+		if separator[0] == 0 {
+			continue // a leading "---" opens the first document rather than splitting one off
+		}
+		starts = append(starts, separator[0])
+	}
+
+	documents := make([]yamlDocument, len(starts))
+	for i, start := range starts {
+		end := len(content)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		documents[i] = yamlDocument{text: content[start:end], offset: int64(start)}
+	}
+
+	return documents
+}
+
 func extractYamlConfigurations(content string) (*[]Configuration, error) {
 	var configurations []Configuration
 
-	yamlDecoder := yaml.NewDecoder(bytes.NewReader([]byte(content)))
+	contentBytes := []byte(content)
+
+	for docIndex, document := range splitYamlDocuments(contentBytes) {
+		// A "---"-delimited chunk can still contain more than one document
+		// (e.g. a "..." document-end marker followed by further content
+		// without another "---"), so decode it to exhaustion rather than
+		// once: a one-shot Decode would silently drop everything after the
+		// first document instead of surfacing the parse error the baseline
+		// streaming decoder reports for that case.
+		decoder := yaml.NewDecoder(bytes.NewReader(document.text))
+		for {
+			var yamlNode yaml.Node
+			err := decoder.Decode(&yamlNode)
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				return nil, newInvalidYamlError(contentBytes, document.offset, docIndex, err)
+			}
 
-	var err error
-	for {
-		var yamlNode yaml.Node
-		err = yamlDecoder.Decode(&yamlNode)
-		if err != nil {
-			if err == io.EOF {
-				break
-			} else {
+			var yamlByteArray bytes.Buffer
+			enc := yaml.NewEncoder(&yamlByteArray)
+			enc.SetIndent(2)
+			if err := enc.Encode(&yamlNode); err != nil {
 				return nil, err
 			}
-		}
-		var yamlByteArray bytes.Buffer
 
-		enc := yaml.NewEncoder(&yamlByteArray)
-		enc.SetIndent(2)
-		err = enc.Encode(&yamlNode)
-		if err != nil {
-			return nil, err
+			jsonByte, err := k8sSigsYaml.YAMLToJSON(yamlByteArray.Bytes())
+			if err != nil {
+				return nil, err
+			}
+
+			configurations = append(configurations, extractConfigurationK8sData(jsonByte, yamlNode))
 		}
+	}
+
+	return &configurations, nil
+}
+
+// newInvalidYamlError builds an InvalidYamlError enriched with a line,
+// column, and source snippet for a decode failure on the document starting
+// at docStartOffset. go-yaml only surfaces a line number relative to the
+// start of the failing document, so it's added to the document's starting
+// line to recover an absolute position within the full source.
+func newInvalidYamlError(content []byte, docStartOffset int64, docIndex int, decodeErr error) *InvalidYamlError {
+	invalidYamlError := &InvalidYamlError{ErrorMessage: decodeErr.Error(), DocIndex: docIndex}
+
+	relativeLine, ok := yamlErrorLine(decodeErr)
+	if !ok {
+		return invalidYamlError
+	}
 
-		jsonByte, err := k8sSigsYaml.YAMLToJSON(yamlByteArray.Bytes())
-		if err != nil {
-			return nil, err
+	docStartLine := 1 + bytes.Count(content[:docStartOffset], []byte{'\n'})
+	absoluteLine := docStartLine + relativeLine - 1
+
+	lineStartOffset := int64(0)
+	for linesSeen := 1; linesSeen < absoluteLine; linesSeen++ {
+		idx := bytes.IndexByte(content[lineStartOffset:], '\n')
+		if idx == -1 {
+			break
 		}
+		lineStartOffset += int64(idx) + 1
+	}
 
-		configurations = append(configurations, extractConfigurationK8sData(jsonByte, yamlNode))
+	// go-yaml's error text carries no column, so point at the line's first
+	// non-whitespace byte rather than hardcoding column 1 for every error.
+	contentStartOffset := lineStartOffset
+	for contentStartOffset < int64(len(content)) && content[contentStartOffset] != '\n' &&
+		(content[contentStartOffset] == ' ' || content[contentStartOffset] == '\t') {
+		contentStartOffset++
 	}
 
-	return &configurations, nil
+	line, col, snippet := HighlightBytePosition(bytes.NewReader(content), contentStartOffset)
+	invalidYamlError.Line = line
+	invalidYamlError.Column = col
+	invalidYamlError.Snippet = snippet
+
+	return invalidYamlError
 }
 
 func extractConfigurationK8sData(content []byte, yamlNode yaml.Node) Configuration {
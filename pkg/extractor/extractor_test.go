@@ -0,0 +1,102 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestExtractYamlConfigurations_MultiDocumentErrorReportsCorrectLine(t *testing.T) {
+	content := "apiVersion: v1\n" +
+		"kind: ConfigMap\n" +
+		"metadata:\n" +
+		"  name: first\n" +
+		"---\n" +
+		"apiVersion: v1\n" +
+		"kind: ConfigMap\n" +
+		"metadata: [not, a, mapping\n"
+
+	_, err := extractYamlConfigurations(content)
+	if err == nil {
+		t.Fatal("expected an error decoding the malformed second document")
+	}
+
+	invalidYamlError, ok := err.(*InvalidYamlError)
+	if !ok {
+		t.Fatalf("expected *InvalidYamlError, got %T", err)
+	}
+
+	if invalidYamlError.DocIndex != 1 {
+		t.Errorf("DocIndex = %d, want 1 (the second document)", invalidYamlError.DocIndex)
+	}
+
+	// The second document spans lines 5-8 of the full (8-line) source; a
+	// buggy offset calculation that treats the decoder's buffered read
+	// position as the document start reports a line number past EOF instead.
+	if invalidYamlError.Line < 5 || invalidYamlError.Line > 8 {
+		t.Errorf("Line = %d, want a line within the second document (5-8)", invalidYamlError.Line)
+	}
+}
+
+func TestExtractYamlConfigurations_DocumentEndMarkerWithoutSeparatorErrors(t *testing.T) {
+	// "..." ends a document without starting a new one via "---"; a second
+	// document directly afterwards is invalid and must surface an error, not
+	// be silently dropped from the result.
+	content := "kind: A\n...\nkind: B\n"
+
+	configurations, err := extractYamlConfigurations(content)
+	if err == nil {
+		t.Fatalf("expected an error, got configurations: %+v", configurations)
+	}
+	if _, ok := err.(*InvalidYamlError); !ok {
+		t.Fatalf("expected *InvalidYamlError, got %T", err)
+	}
+}
+
+func TestNewInvalidYamlError_ColumnTracksLineIndentation(t *testing.T) {
+	shallow := []byte("key: [unterminated\n")
+	deep := []byte("      key: [unterminated\n")
+
+	shallowErr := newInvalidYamlError(shallow, 0, 0, &testLineError{line: 1})
+	deepErr := newInvalidYamlError(deep, 0, 0, &testLineError{line: 1})
+
+	if shallowErr.Column == deepErr.Column {
+		t.Fatalf("expected Column to vary with indentation, both reported %d", shallowErr.Column)
+	}
+	if deepErr.Column != 7 {
+		t.Errorf("Column = %d, want 7 (first non-whitespace byte)", deepErr.Column)
+	}
+}
+
+// testLineError mimics go-yaml's "yaml: line N: ..." error text without
+// requiring an actual malformed document.
+type testLineError struct{ line int }
+
+func (e *testLineError) Error() string {
+	return "yaml: line " + strconv.Itoa(e.line) + ": synthetic test error"
+}
+
+func TestExtractConfigurationsFromYamlFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "manifest.yaml")
+	content := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test-cm\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	configurations, absolutePath, invalidFile := ExtractConfigurationsFromYamlFile(path)
+	if invalidFile != nil {
+		t.Fatalf("unexpected InvalidFile: %v", invalidFile.ValidationErrors)
+	}
+	if !strings.HasSuffix(absolutePath, "manifest.yaml") {
+		t.Errorf("absolutePath = %q, want suffix manifest.yaml", absolutePath)
+	}
+	if len(*configurations) != 1 {
+		t.Fatalf("got %d configurations, want 1", len(*configurations))
+	}
+	if (*configurations)[0].MetadataName != "test-cm" {
+		t.Errorf("MetadataName = %q, want test-cm", (*configurations)[0].MetadataName)
+	}
+}
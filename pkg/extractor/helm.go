@@ -0,0 +1,95 @@
+package extractor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+)
+
+// ChartLoader abstracts loading a chart from chartPath so tests can inject a
+// fake chart filesystem, mirroring the role FileReader plays for plain files.
+type ChartLoader interface {
+	Load(chartPath string) (*chart.Chart, error)
+}
+
+type diskChartLoader struct{}
+
+func (diskChartLoader) Load(chartPath string) (*chart.Chart, error) {
+	return loader.Load(chartPath)
+}
+
+// ExtractConfigurationsFromHelmChart renders the chart at chartPath with
+// values merged over the chart's own values.yaml, then runs every rendered
+// template through the same extraction pipeline used for plain YAML files.
+// Each resulting Configuration records the template that produced it in
+// SourceTemplate, so validation errors can be attributed to a specific file
+// inside the chart rather than the chart as a whole.
+func ExtractConfigurationsFromHelmChart(chartPath string, values map[string]interface{}, releaseName, namespace string) (*[]Configuration, *InvalidFile) {
+	return extractConfigurationsFromHelmChart(diskChartLoader{}, chartPath, values, releaseName, namespace)
+}
+
+func extractConfigurationsFromHelmChart(chartLoader ChartLoader, chartPath string, values map[string]interface{}, releaseName, namespace string) (*[]Configuration, *InvalidFile) {
+	loadedChart, err := chartLoader.Load(chartPath)
+	if err != nil {
+		return nil, &InvalidFile{Path: chartPath, ValidationErrors: []error{&InvalidYamlError{ErrorMessage: err.Error()}}}
+	}
+
+	mergedValues, err := chartutil.CoalesceValues(loadedChart, values)
+	if err != nil {
+		return nil, &InvalidFile{Path: chartPath, ValidationErrors: []error{&InvalidYamlError{ErrorMessage: err.Error()}}}
+	}
+
+	renderValues, err := chartutil.ToRenderValues(loadedChart, mergedValues, chartutil.ReleaseOptions{
+		Name:      releaseName,
+		Namespace: namespace,
+	}, nil)
+	if err != nil {
+		return nil, &InvalidFile{Path: chartPath, ValidationErrors: []error{&InvalidYamlError{ErrorMessage: err.Error()}}}
+	}
+
+	rendered, err := engine.Render(loadedChart, renderValues)
+	if err != nil {
+		return nil, &InvalidFile{Path: chartPath, ValidationErrors: []error{&InvalidYamlError{ErrorMessage: err.Error()}}}
+	}
+
+	templateNames := make([]string, 0, len(rendered))
+	for name := range rendered {
+		templateNames = append(templateNames, name)
+	}
+	sort.Strings(templateNames)
+
+	var configurations []Configuration
+	var validationErrors []error
+	for _, templateName := range templateNames {
+		if strings.HasSuffix(templateName, "NOTES.txt") || strings.TrimSpace(rendered[templateName]) == "" {
+			continue
+		}
+
+		templateConfigurations, err := ParseYaml(rendered[templateName])
+		if err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("template %s: %w", templateName, err))
+			continue
+		}
+
+		for _, configuration := range *templateConfigurations {
+			configuration.SourceTemplate = templateName
+			configurations = append(configurations, configuration)
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return &configurations, &InvalidFile{Path: chartPath, ValidationErrors: validationErrors}
+	}
+
+	mutatedConfigurations, err := ApplyTimeMutations(configurations)
+	if err != nil {
+		return &configurations, &InvalidFile{Path: chartPath, ValidationErrors: []error{err}}
+	}
+
+	return &mutatedConfigurations, nil
+}
@@ -0,0 +1,94 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+type fakeChartLoader struct {
+	chart *chart.Chart
+}
+
+func (f fakeChartLoader) Load(chartPath string) (*chart.Chart, error) {
+	return f.chart, nil
+}
+
+func testChart() *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:       "test-chart",
+			Version:    "0.1.0",
+			APIVersion: "v2",
+		},
+		Templates: []*chart.File{
+			{
+				Name: "templates/configmap.yaml",
+				Data: []byte(strings.Join([]string{
+					"apiVersion: v1",
+					"kind: ConfigMap",
+					"metadata:",
+					"  name: {{ .Release.Name }}-cm",
+					"data:",
+					"  foo: {{ .Values.foo }}",
+					"",
+				}, "\n")),
+			},
+		},
+		Values: map[string]interface{}{"foo": "default"},
+	}
+}
+
+func TestExtractConfigurationsFromHelmChart_RendersWithOverriddenValues(t *testing.T) {
+	configurations, invalidFile := extractConfigurationsFromHelmChart(
+		fakeChartLoader{chart: testChart()},
+		"ignored-because-of-fake-loader",
+		map[string]interface{}{"foo": "overridden"},
+		"my-release",
+		"my-namespace",
+	)
+	if invalidFile != nil {
+		t.Fatalf("unexpected InvalidFile: %v", invalidFile.ValidationErrors)
+	}
+	if len(*configurations) != 1 {
+		t.Fatalf("got %d configurations, want 1", len(*configurations))
+	}
+
+	configuration := (*configurations)[0]
+	if configuration.MetadataName != "my-release-cm" {
+		t.Errorf("MetadataName = %q, want my-release-cm", configuration.MetadataName)
+	}
+	if !strings.Contains(configuration.SourceTemplate, "templates/configmap.yaml") {
+		t.Errorf("SourceTemplate = %q, want it to identify templates/configmap.yaml", configuration.SourceTemplate)
+	}
+	if !strings.Contains(string(configuration.Payload), "overridden") {
+		t.Errorf("Payload = %s, want it to contain the overridden value", configuration.Payload)
+	}
+}
+
+func TestExtractConfigurationsFromHelmChart_InvalidTemplateIsAttributed(t *testing.T) {
+	badChart := testChart()
+	badChart.Templates[0].Data = []byte("apiVersion: v1\nkind: [not, a, mapping\n")
+
+	_, invalidFile := extractConfigurationsFromHelmChart(
+		fakeChartLoader{chart: badChart},
+		"ignored",
+		nil,
+		"my-release",
+		"my-namespace",
+	)
+	if invalidFile == nil {
+		t.Fatal("expected an InvalidFile for a template that renders invalid YAML")
+	}
+
+	found := false
+	for _, validationError := range invalidFile.ValidationErrors {
+		if strings.Contains(validationError.Error(), "templates/configmap.yaml") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a validation error naming templates/configmap.yaml, got %v", invalidFile.ValidationErrors)
+	}
+}
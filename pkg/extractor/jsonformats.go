@@ -0,0 +1,233 @@
+package extractor
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-jsonnet"
+	"gopkg.in/yaml.v3"
+)
+
+// JsonnetImportPaths is consulted by every .jsonnet/.libsonnet evaluation.
+// Callers that vendor jsonnet libraries outside the file's own directory
+// should set this before extraction runs.
+var JsonnetImportPaths []string
+
+// maxJSONLineBytes bounds a single .jsonl line/Configuration well past
+// bufio.Scanner's 64KB default, which is too small for real Kubernetes
+// manifests (e.g. a ConfigMap or Secret with a sizable data block).
+const maxJSONLineBytes = 10 * 1024 * 1024
+
+type contentFormat int
+
+const (
+	formatYAML contentFormat = iota
+	formatJSON
+	formatJSONLines
+	formatJsonnet
+)
+
+// detectContentFormat sniffs how content should be decoded from name's
+// extension alone: .json, .jsonl, .jsonnet, and .libsonnet are unambiguous.
+// Anything else (including content that merely looks like JSON, such as a
+// YAML document in flow style) is left as formatYAML, since a multi-document
+// YAML stream can legitimately start with '{' for its first document.
+func detectContentFormat(name string) contentFormat {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return formatJSON
+	case ".jsonl":
+		return formatJSONLines
+	case ".jsonnet", ".libsonnet":
+		return formatJsonnet
+	}
+
+	return formatYAML
+}
+
+// looksLikeJSON reports whether content's first non-whitespace byte opens a
+// JSON object or array. It's only a hint used to prefer encoding/json's
+// error messages over go-yaml's; callers must still fall back to YAML when
+// the JSON decode fails, since a flow-style YAML document also matches.
+func looksLikeJSON(content string) bool {
+	trimmed := strings.TrimLeft(content, " \t\r\n")
+	return strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")
+}
+
+// ParseContent extracts Configurations from content, sniffing its format
+// from name's extension and, failing that, from the content itself. .json
+// content decodes with encoding/json, with a top-level array or List kind
+// expanding into one Configuration per element (matching how kubectl treats
+// List kinds); .jsonl decodes one Configuration per line; .jsonnet/.libsonnet
+// are evaluated to JSON first and then expanded the same way as .json. For
+// any other name, content that parses as JSON becomes a single
+// Configuration, arrays included, without expansion. Anything else is parsed
+// as YAML.
+func ParseContent(name, content string) (*[]Configuration, error) {
+	switch detectContentFormat(name) {
+	case formatJSONLines:
+		return parseJSONLines(content)
+	case formatJsonnet:
+		return parseJsonnet(name, content)
+	case formatJSON:
+		return parseJSON(content)
+	}
+
+	if looksLikeJSON(content) {
+		// Only decode here, don't expand a top-level array/List: this branch
+		// is reached for any name whose extension didn't force a format, so
+		// content could just as easily be a flow-style YAML document (e.g. a
+		// ".yaml" file containing "[a, b, c]") rather than an actual JSON
+		// List. Expanding it here would silently change the Configuration
+		// count for existing YAML callers; only the extension-forced JSON
+		// paths above opt into that behavior.
+		if configurations, err := parseJSONSingleDocument(content); err == nil {
+			return configurations, nil
+		}
+	}
+
+	return ParseYaml(content)
+}
+
+func parseJSON(content string) (*[]Configuration, error) {
+	return decodeJSON(content, true)
+}
+
+// parseJSONSingleDocument decodes content as one JSON value without
+// expanding a top-level array or List kind into multiple Configurations.
+func parseJSONSingleDocument(content string) (*[]Configuration, error) {
+	return decodeJSON(content, false)
+}
+
+func decodeJSON(content string, expandLists bool) (*[]Configuration, error) {
+	var raw interface{}
+	if err := json.Unmarshal([]byte(content), &raw); err != nil {
+		return nil, newInvalidJSONError(content, err)
+	}
+
+	var configurations []Configuration
+	if expandLists {
+		if items, ok := jsonListItems(raw); ok {
+			for _, item := range items {
+				configuration, err := configurationFromJSONValue(item)
+				if err != nil {
+					return nil, err
+				}
+				configurations = append(configurations, configuration)
+			}
+			return &configurations, nil
+		}
+	}
+
+	configuration, err := configurationFromJSONValue(raw)
+	if err != nil {
+		return nil, err
+	}
+	configurations = append(configurations, configuration)
+
+	return &configurations, nil
+}
+
+// jsonListItems returns the elements raw should expand into, matching how
+// kubectl fans out both a bare JSON array and a Kubernetes List object
+// (kind: List, items: [...]) into one object per element.
+func jsonListItems(raw interface{}) ([]interface{}, bool) {
+	if items, ok := raw.([]interface{}); ok {
+		return items, true
+	}
+
+	object, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	if kind, _ := object["kind"].(string); kind != "List" {
+		return nil, false
+	}
+	items, ok := object["items"].([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	return items, true
+}
+
+func parseJSONLines(content string) (*[]Configuration, error) {
+	var configurations []Configuration
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLineBytes)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var value interface{}
+		if err := json.Unmarshal([]byte(line), &value); err != nil {
+			invalidError := newInvalidJSONError(line, err)
+			invalidError.Line = lineNumber
+			return nil, invalidError
+		}
+
+		configuration, err := configurationFromJSONValue(value)
+		if err != nil {
+			return nil, err
+		}
+		configurations = append(configurations, configuration)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &configurations, nil
+}
+
+func parseJsonnet(name, content string) (*[]Configuration, error) {
+	vm := jsonnet.MakeVM()
+	if len(JsonnetImportPaths) > 0 {
+		vm.Importer(&jsonnet.FileImporter{JPaths: JsonnetImportPaths})
+	}
+
+	evaluated, err := vm.EvaluateAnonymousSnippet(name, content)
+	if err != nil {
+		return nil, &InvalidYamlError{ErrorMessage: err.Error()}
+	}
+
+	return parseJSON(evaluated)
+}
+
+func configurationFromJSONValue(value interface{}) (Configuration, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return Configuration{}, err
+	}
+
+	var yamlNode yaml.Node
+	if err := yamlNode.Encode(value); err != nil {
+		return Configuration{}, err
+	}
+
+	return extractConfigurationK8sData(payload, yamlNode), nil
+}
+
+// newInvalidJSONError surfaces a json.SyntaxError's byte Offset through the
+// same HighlightBytePosition helper used for YAML decode errors, so JSON,
+// JSONL, and YAML inputs all report compiler-style diagnostics uniformly.
+func newInvalidJSONError(content string, decodeErr error) *InvalidYamlError {
+	invalidError := &InvalidYamlError{ErrorMessage: decodeErr.Error()}
+
+	var syntaxError *json.SyntaxError
+	if errors.As(decodeErr, &syntaxError) {
+		line, col, snippet := HighlightBytePosition(strings.NewReader(content), syntaxError.Offset)
+		invalidError.Line = line
+		invalidError.Column = col
+		invalidError.Snippet = snippet
+	}
+
+	return invalidError
+}
@@ -0,0 +1,154 @@
+package extractor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectContentFormat(t *testing.T) {
+	cases := []struct {
+		name string
+		want contentFormat
+	}{
+		{"manifest.json", formatJSON},
+		{"manifest.jsonl", formatJSONLines},
+		{"manifest.jsonnet", formatJsonnet},
+		{"manifest.libsonnet", formatJsonnet},
+		{"manifest.yaml", formatYAML},
+		{"manifest.yml", formatYAML},
+		{"stdin", formatYAML},
+	}
+	for _, c := range cases {
+		if got := detectContentFormat(c.name); got != c.want {
+			t.Errorf("detectContentFormat(%q) = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestParseJSON_ExpandsBareArray(t *testing.T) {
+	content := `[{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a"}},{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"b"}}]`
+
+	configurations, err := parseJSON(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*configurations) != 2 {
+		t.Fatalf("got %d configurations, want 2", len(*configurations))
+	}
+	if (*configurations)[0].MetadataName != "a" || (*configurations)[1].MetadataName != "b" {
+		t.Errorf("unexpected names: %q, %q", (*configurations)[0].MetadataName, (*configurations)[1].MetadataName)
+	}
+}
+
+func TestParseJSON_ExpandsKubectlListKind(t *testing.T) {
+	content := `{
+		"apiVersion": "v1",
+		"kind": "List",
+		"items": [
+			{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a"}},
+			{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"b"}}
+		]
+	}`
+
+	configurations, err := parseJSON(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*configurations) != 2 {
+		t.Fatalf("got %d configurations, want 2 (expanded from List.items)", len(*configurations))
+	}
+}
+
+func TestParseJSON_SyntaxErrorReportsLineAndColumn(t *testing.T) {
+	content := "{\n  \"kind\": \"ConfigMap\",\n  \"metadata\": {\n"
+
+	_, err := parseJSON(content)
+	if err == nil {
+		t.Fatal("expected a syntax error for unclosed JSON")
+	}
+	invalidError, ok := err.(*InvalidYamlError)
+	if !ok {
+		t.Fatalf("expected *InvalidYamlError, got %T", err)
+	}
+	if invalidError.Line == 0 {
+		t.Errorf("expected a non-zero Line for the JSON syntax error, got %+v", invalidError)
+	}
+}
+
+func TestParseJSONLines_OneConfigurationPerLine(t *testing.T) {
+	content := "" +
+		`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a"}}` + "\n" +
+		"\n" +
+		`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"b"}}` + "\n"
+
+	configurations, err := parseJSONLines(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*configurations) != 2 {
+		t.Fatalf("got %d configurations, want 2 (blank lines skipped)", len(*configurations))
+	}
+}
+
+func TestParseJSONLines_MalformedLineReportsLineNumber(t *testing.T) {
+	content := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a"}}` + "\n" + "not json\n"
+
+	_, err := parseJSONLines(content)
+	if err == nil {
+		t.Fatal("expected an error for the malformed second line")
+	}
+	invalidError, ok := err.(*InvalidYamlError)
+	if !ok {
+		t.Fatalf("expected *InvalidYamlError, got %T", err)
+	}
+	if invalidError.Line != 2 {
+		t.Errorf("Line = %d, want 2", invalidError.Line)
+	}
+}
+
+func TestParseContent_MultiDocumentYamlStartingWithFlowStyleFallsBackToYaml(t *testing.T) {
+	// Regression test: a first document that looks JSON-ish must not force
+	// the whole multi-document stream through encoding/json, which can't
+	// parse past the "---" separator.
+	content := "{apiVersion: v1, kind: ConfigMap, metadata: {name: a}}\n" +
+		"---\n" +
+		"apiVersion: v1\n" +
+		"kind: ConfigMap\n" +
+		"metadata:\n" +
+		"  name: b\n"
+
+	configurations, err := ParseContent("manifest.yaml", content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*configurations) != 2 {
+		t.Fatalf("got %d configurations, want 2", len(*configurations))
+	}
+	names := []string{(*configurations)[0].MetadataName, (*configurations)[1].MetadataName}
+	if !strings.Contains(strings.Join(names, ","), "a") || !strings.Contains(strings.Join(names, ","), "b") {
+		t.Errorf("unexpected names: %v", names)
+	}
+}
+
+func TestParseContent_FlowStyleYamlArrayIsNotExpanded(t *testing.T) {
+	// A ".yaml" file whose single document is a flow-style sequence looks
+	// JSON-ish to looksLikeJSON, but it's not a JSON List and must stay a
+	// single Configuration rather than fan out one per element.
+	configurations, err := ParseContent("manifest.yaml", "[a, b, c]\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*configurations) != 1 {
+		t.Fatalf("got %d configurations, want 1 (content-sniffed JSON must not expand lists)", len(*configurations))
+	}
+}
+
+func TestParseContent_PureJSONFileUsesJSONDecoder(t *testing.T) {
+	configurations, err := ParseContent("manifest.json", `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"from-json"}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*configurations) != 1 || (*configurations)[0].MetadataName != "from-json" {
+		t.Fatalf("got %+v, want one configuration named from-json", *configurations)
+	}
+}
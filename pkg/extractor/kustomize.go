@@ -0,0 +1,91 @@
+package extractor
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+var kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// IsKustomizationDirectory reports whether path contains a kustomization
+// entry point that ExtractConfigurationsFromKustomization can render.
+func IsKustomizationDirectory(path string) bool {
+	for _, name := range kustomizationFileNames {
+		if fileInfo, err := os.Stat(filepath.Join(path, name)); err == nil && !fileInfo.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// loadDirectoryIntoMemFs copies root's contents into an in-memory filesystem
+// so krusty can resolve resources/bases/patches without touching disk again.
+func loadDirectoryIntoMemFs(root string) (filesys.FileSystem, error) {
+	fSys := filesys.MakeFsInMemory()
+
+	err := filepath.Walk(root, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		data, readErr := os.ReadFile(path)
+		if readErr != nil {
+			return readErr
+		}
+
+		return fSys.WriteFile(path, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fSys, nil
+}
+
+// ExtractConfigurationsFromKustomization renders the kustomization rooted at
+// path (resources, bases, patches, configMapGenerator, namePrefix, etc.) via
+// krusty and runs the resulting YAML stream through the same extraction
+// pipeline used for plain manifests, so callers lint the fully-overlaid
+// resources that would actually be applied to the cluster.
+func ExtractConfigurationsFromKustomization(path string) (*[]Configuration, string, *InvalidFile) {
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, "", &InvalidFile{Path: path, ValidationErrors: []error{&InvalidYamlError{ErrorMessage: err.Error()}}}
+	}
+
+	fSys, err := loadDirectoryIntoMemFs(absolutePath)
+	if err != nil {
+		return nil, "", &InvalidFile{Path: absolutePath, ValidationErrors: []error{&InvalidYamlError{ErrorMessage: err.Error()}}}
+	}
+
+	kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	resMap, err := kustomizer.Run(fSys, absolutePath)
+	if err != nil {
+		return nil, "", &InvalidFile{Path: absolutePath, ValidationErrors: []error{&InvalidYamlError{ErrorMessage: fmt.Sprintf("kustomize build failed: %s", err.Error())}}}
+	}
+
+	renderedYaml, err := resMap.AsYaml()
+	if err != nil {
+		return nil, "", &InvalidFile{Path: absolutePath, ValidationErrors: []error{&InvalidYamlError{ErrorMessage: err.Error()}}}
+	}
+
+	configurations, err := ParseYaml(string(renderedYaml))
+	if err != nil {
+		return nil, "", &InvalidFile{Path: absolutePath, ValidationErrors: []error{err}}
+	}
+
+	mutatedConfigurations, err := ApplyTimeMutations(*configurations)
+	if err != nil {
+		return nil, "", &InvalidFile{Path: absolutePath, ValidationErrors: []error{err}}
+	}
+
+	return &mutatedConfigurations, absolutePath, nil
+}
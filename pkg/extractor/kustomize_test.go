@@ -0,0 +1,97 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsKustomizationDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if IsKustomizationDirectory(dir) {
+		t.Fatal("empty directory should not be detected as a kustomization")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("resources: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if !IsKustomizationDirectory(dir) {
+		t.Fatal("directory with kustomization.yaml should be detected as a kustomization")
+	}
+}
+
+func TestExtractConfigurationsFromKustomization_RendersOverlay(t *testing.T) {
+	dir := t.TempDir()
+
+	configMapYAML := "" +
+		"apiVersion: v1\n" +
+		"kind: ConfigMap\n" +
+		"metadata:\n" +
+		"  name: base-cm\n" +
+		"data:\n" +
+		"  foo: bar\n"
+	if err := os.WriteFile(filepath.Join(dir, "configmap.yaml"), []byte(configMapYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	kustomizationYAML := "" +
+		"resources:\n" +
+		"- configmap.yaml\n" +
+		"namePrefix: prod-\n" +
+		"configMapGenerator:\n" +
+		"- name: extra-cm\n" +
+		"  literals:\n" +
+		"  - key=value\n" +
+		"generatorOptions:\n" +
+		"  disableNameSuffixHash: true\n"
+	if err := os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte(kustomizationYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	configurations, _, invalidFile := ExtractConfigurationsFromKustomization(dir)
+	if invalidFile != nil {
+		t.Fatalf("unexpected InvalidFile: %v", invalidFile.ValidationErrors)
+	}
+
+	names := make([]string, 0, len(*configurations))
+	for _, configuration := range *configurations {
+		names = append(names, configuration.MetadataName)
+	}
+
+	if !containsString(names, "prod-base-cm") {
+		t.Errorf("expected namePrefix applied to the resource from configmap.yaml, got names %v", names)
+	}
+	if !containsString(names, "prod-extra-cm") {
+		t.Errorf("expected namePrefix applied to the configMapGenerator output, got names %v", names)
+	}
+}
+
+func containsString(list []string, target string) bool {
+	for _, item := range list {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLoadDirectoryIntoMemFs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("resources:\n- configmap.yaml\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "configmap.yaml"), []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fSys, err := loadDirectoryIntoMemFs(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !fSys.Exists(filepath.Join(dir, "kustomization.yaml")) {
+		t.Error("expected kustomization.yaml to exist in the in-memory filesystem")
+	}
+	if !fSys.Exists(filepath.Join(dir, "configmap.yaml")) {
+		t.Error("expected configmap.yaml to exist in the in-memory filesystem")
+	}
+}
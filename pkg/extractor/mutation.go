@@ -0,0 +1,288 @@
+package extractor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ApplyTimeMutationAnnotation marks a resource whose payload contains
+// placeholder values that should be resolved from other resources in the
+// same set before policy checks run.
+const ApplyTimeMutationAnnotation = "config.datree.io/apply-time-mutation"
+
+type resourceRef struct {
+	ApiVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Name       string `yaml:"name"`
+	Namespace  string `yaml:"namespace"`
+}
+
+type fieldSubstitution struct {
+	SourceRef  resourceRef `yaml:"sourceRef"`
+	SourcePath string      `yaml:"sourcePath"`
+	TargetPath string      `yaml:"targetPath"`
+	Token      string      `yaml:"token"`
+}
+
+type configurationKey struct {
+	ApiVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+}
+
+func configurationKeyOf(configuration Configuration) configurationKey {
+	return configurationKey{
+		ApiVersion: configuration.ApiVersion,
+		Kind:       configuration.Kind,
+		Namespace:  configurationNamespace(configuration),
+		Name:       configuration.MetadataName,
+	}
+}
+
+func configurationNamespace(configuration Configuration) string {
+	var jsonObject map[string]interface{}
+	if err := json.Unmarshal(configuration.Payload, &jsonObject); err != nil {
+		return ""
+	}
+	metadata, ok := jsonObject["metadata"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	namespace, _ := metadata["namespace"].(string)
+	return namespace
+}
+
+// ApplyTimeMutations resolves every config.datree.io/apply-time-mutation
+// annotation in configurations, substituting fields copied from other
+// resources in the same set, so downstream policy checks see fully-resolved
+// manifests instead of ones with placeholder values. It mutates and returns
+// the same slice; a resource without the annotation passes through
+// unchanged.
+func ApplyTimeMutations(configurations []Configuration) ([]Configuration, error) {
+	index := make(map[configurationKey]*Configuration, len(configurations))
+	for i := range configurations {
+		index[configurationKeyOf(configurations[i])] = &configurations[i]
+	}
+
+	mutated := make(map[configurationKey]bool, len(configurations))
+	for i := range configurations {
+		key := configurationKeyOf(configurations[i])
+		if err := applyTimeMutateConfiguration(key, index, mutated, map[configurationKey]bool{}); err != nil {
+			return nil, err
+		}
+	}
+
+	return configurations, nil
+}
+
+func applyTimeMutateConfiguration(key configurationKey, index map[configurationKey]*Configuration, mutated, visiting map[configurationKey]bool) error {
+	if mutated[key] {
+		return nil
+	}
+	if visiting[key] {
+		return fmt.Errorf("%s: cyclic reference involving %s/%s", ApplyTimeMutationAnnotation, key.Kind, key.Name)
+	}
+
+	target := index[key]
+	annotationValue, ok := target.Annotations[ApplyTimeMutationAnnotation]
+	if !ok {
+		mutated[key] = true
+		return nil
+	}
+
+	substitutions, err := parseApplyTimeMutations(annotationValue)
+	if err != nil {
+		return err
+	}
+
+	visiting[key] = true
+	for _, substitution := range substitutions {
+		sourceKey := configurationKey{
+			ApiVersion: substitution.SourceRef.ApiVersion,
+			Kind:       substitution.SourceRef.Kind,
+			Namespace:  substitution.SourceRef.Namespace,
+			Name:       substitution.SourceRef.Name,
+		}
+		if sourceKey == key {
+			return fmt.Errorf("%s: %s/%s cannot reference itself as sourceRef", ApplyTimeMutationAnnotation, key.Kind, key.Name)
+		}
+
+		source, ok := index[sourceKey]
+		if !ok {
+			return fmt.Errorf("%s: source %s/%s/%s referenced by %s/%s not found", ApplyTimeMutationAnnotation, sourceKey.Kind, sourceKey.Namespace, sourceKey.Name, key.Kind, key.Name)
+		}
+
+		if err := applyTimeMutateConfiguration(sourceKey, index, mutated, visiting); err != nil {
+			return err
+		}
+
+		if err := substituteField(target, source, substitution); err != nil {
+			return err
+		}
+	}
+	delete(visiting, key)
+	mutated[key] = true
+
+	return nil
+}
+
+func parseApplyTimeMutations(annotationValue interface{}) ([]fieldSubstitution, error) {
+	raw, ok := annotationValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s: annotation value must be a string", ApplyTimeMutationAnnotation)
+	}
+
+	var substitutions []fieldSubstitution
+	if err := yaml.Unmarshal([]byte(raw), &substitutions); err != nil {
+		return nil, fmt.Errorf("%s: %w", ApplyTimeMutationAnnotation, err)
+	}
+
+	return substitutions, nil
+}
+
+// substituteField copies the value at substitution.SourcePath in source's
+// payload into substitution.TargetPath in target's payload (or, when Token
+// is set, replaces just that substring of the existing string value there),
+// then re-serializes target's Payload and YamlNode so later readers see the
+// resolved value.
+func substituteField(target, source *Configuration, substitution fieldSubstitution) error {
+	var sourceObject interface{}
+	if err := json.Unmarshal(source.Payload, &sourceObject); err != nil {
+		return fmt.Errorf("%s: decoding source payload: %w", ApplyTimeMutationAnnotation, err)
+	}
+
+	sourceValue, err := jsonPathGet(sourceObject, substitution.SourcePath)
+	if err != nil {
+		return fmt.Errorf("%s: reading sourcePath %s: %w", ApplyTimeMutationAnnotation, substitution.SourcePath, err)
+	}
+
+	var targetObject interface{}
+	if err := json.Unmarshal(target.Payload, &targetObject); err != nil {
+		return fmt.Errorf("%s: decoding target payload: %w", ApplyTimeMutationAnnotation, err)
+	}
+
+	newValue := sourceValue
+	if substitution.Token != "" {
+		currentValue, err := jsonPathGet(targetObject, substitution.TargetPath)
+		if err != nil {
+			return fmt.Errorf("%s: reading targetPath %s: %w", ApplyTimeMutationAnnotation, substitution.TargetPath, err)
+		}
+		currentString, ok := currentValue.(string)
+		if !ok {
+			return fmt.Errorf("%s: targetPath %s is not a string, cannot substitute token %q", ApplyTimeMutationAnnotation, substitution.TargetPath, substitution.Token)
+		}
+		newValue = strings.ReplaceAll(currentString, substitution.Token, fmt.Sprintf("%v", sourceValue))
+	}
+
+	if err := jsonPathSet(targetObject, substitution.TargetPath, newValue); err != nil {
+		return fmt.Errorf("%s: writing targetPath %s: %w", ApplyTimeMutationAnnotation, substitution.TargetPath, err)
+	}
+
+	newPayload, err := json.Marshal(targetObject)
+	if err != nil {
+		return fmt.Errorf("%s: re-encoding target payload: %w", ApplyTimeMutationAnnotation, err)
+	}
+	target.Payload = newPayload
+
+	var newYamlNode yaml.Node
+	if err := newYamlNode.Encode(targetObject); err != nil {
+		return fmt.Errorf("%s: refreshing yaml node: %w", ApplyTimeMutationAnnotation, err)
+	}
+	target.YamlNode = newYamlNode
+
+	return nil
+}
+
+// splitJSONPath turns a JSONPath like "$.spec.clusterIP" into
+// ["spec", "clusterIP"]. Only the subset of JSONPath used by
+// apply-time-mutation substitutions (dotted fields and [n] array indices)
+// is supported.
+func splitJSONPath(path string) []string {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+func parsePathSegment(segment string) (key string, index int, hasIndex bool) {
+	openBracket := strings.IndexByte(segment, '[')
+	if openBracket == -1 {
+		return segment, 0, false
+	}
+	key = segment[:openBracket]
+	index, _ = strconv.Atoi(strings.TrimSuffix(segment[openBracket+1:], "]"))
+	return key, index, true
+}
+
+func jsonPathGet(object interface{}, path string) (interface{}, error) {
+	current := object
+	for _, segment := range splitJSONPath(path) {
+		key, index, hasIndex := parsePathSegment(segment)
+
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("segment %q: expected an object", segment)
+		}
+		value, ok := m[key]
+		if !ok {
+			return nil, fmt.Errorf("segment %q: key not found", segment)
+		}
+
+		if hasIndex {
+			arr, ok := value.([]interface{})
+			if !ok || index >= len(arr) {
+				return nil, fmt.Errorf("segment %q: expected an array with index %d", segment, index)
+			}
+			value = arr[index]
+		}
+		current = value
+	}
+
+	return current, nil
+}
+
+func jsonPathSet(object interface{}, path string, value interface{}) error {
+	segments := splitJSONPath(path)
+	if len(segments) == 0 {
+		return fmt.Errorf("targetPath must not be empty")
+	}
+
+	current := object
+	for i, segment := range segments {
+		key, index, hasIndex := parsePathSegment(segment)
+
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("segment %q: expected an object", segment)
+		}
+
+		last := i == len(segments)-1
+		if !hasIndex {
+			if last {
+				m[key] = value
+				return nil
+			}
+			current = m[key]
+			continue
+		}
+
+		arr, ok := m[key].([]interface{})
+		if !ok || index >= len(arr) {
+			return fmt.Errorf("segment %q: expected an array with index %d", segment, index)
+		}
+		if last {
+			arr[index] = value
+			return nil
+		}
+		current = arr[index]
+	}
+
+	return nil
+}
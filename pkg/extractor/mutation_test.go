@@ -0,0 +1,174 @@
+package extractor
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestApplyTimeMutations_SubstitutesFieldFromSource(t *testing.T) {
+	source := Configuration{
+		ApiVersion:   "v1",
+		Kind:         "Service",
+		MetadataName: "my-svc",
+		Payload:      []byte(`{"apiVersion":"v1","kind":"Service","metadata":{"name":"my-svc","namespace":"default"},"spec":{"clusterIP":"10.0.0.5"}}`),
+	}
+
+	target := Configuration{
+		ApiVersion:   "apps/v1",
+		Kind:         "Deployment",
+		MetadataName: "my-deploy",
+		Annotations: map[string]interface{}{
+			ApplyTimeMutationAnnotation: "" +
+				"- sourceRef:\n" +
+				"    apiVersion: v1\n" +
+				"    kind: Service\n" +
+				"    name: my-svc\n" +
+				"    namespace: default\n" +
+				"  sourcePath: $.spec.clusterIP\n" +
+				"  targetPath: $.spec.env.value\n",
+		},
+		Payload: []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"my-deploy","namespace":"default"},"spec":{"env":{"name":"SERVICE_IP","value":"PLACEHOLDER"}}}`),
+	}
+
+	mutated, err := ApplyTimeMutations([]Configuration{source, target})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(mutated[1].Payload, &result); err != nil {
+		t.Fatalf("re-decoding mutated payload: %v", err)
+	}
+
+	value := result["spec"].(map[string]interface{})["env"].(map[string]interface{})["value"]
+	if value != "10.0.0.5" {
+		t.Errorf("spec.env.value = %v, want 10.0.0.5", value)
+	}
+}
+
+func TestApplyTimeMutations_TokenSubstitution(t *testing.T) {
+	source := Configuration{
+		ApiVersion:   "v1",
+		Kind:         "Service",
+		MetadataName: "my-svc",
+		Payload:      []byte(`{"apiVersion":"v1","kind":"Service","metadata":{"name":"my-svc","namespace":"default"},"spec":{"clusterIP":"10.0.0.5"}}`),
+	}
+
+	target := Configuration{
+		ApiVersion:   "apps/v1",
+		Kind:         "Deployment",
+		MetadataName: "my-deploy",
+		Annotations: map[string]interface{}{
+			ApplyTimeMutationAnnotation: "" +
+				"- sourceRef:\n" +
+				"    apiVersion: v1\n" +
+				"    kind: Service\n" +
+				"    name: my-svc\n" +
+				"    namespace: default\n" +
+				"  sourcePath: $.spec.clusterIP\n" +
+				"  targetPath: $.spec.url\n" +
+				"  token: CLUSTER_IP\n",
+		},
+		Payload: []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"my-deploy","namespace":"default"},"spec":{"url":"http://CLUSTER_IP:8080"}}`),
+	}
+
+	mutated, err := ApplyTimeMutations([]Configuration{source, target})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(mutated[1].Payload, &result); err != nil {
+		t.Fatalf("re-decoding mutated payload: %v", err)
+	}
+
+	url := result["spec"].(map[string]interface{})["url"]
+	if url != "http://10.0.0.5:8080" {
+		t.Errorf("spec.url = %v, want http://10.0.0.5:8080", url)
+	}
+}
+
+func TestApplyTimeMutations_SelfReferenceIsRejected(t *testing.T) {
+	target := Configuration{
+		ApiVersion:   "apps/v1",
+		Kind:         "Deployment",
+		MetadataName: "my-deploy",
+		Annotations: map[string]interface{}{
+			ApplyTimeMutationAnnotation: "" +
+				"- sourceRef:\n" +
+				"    apiVersion: apps/v1\n" +
+				"    kind: Deployment\n" +
+				"    name: my-deploy\n" +
+				"    namespace: default\n" +
+				"  sourcePath: $.spec.replicas\n" +
+				"  targetPath: $.spec.replicas\n",
+		},
+		Payload: []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"my-deploy","namespace":"default"},"spec":{"replicas":1}}`),
+	}
+
+	_, err := ApplyTimeMutations([]Configuration{target})
+	if err == nil {
+		t.Fatal("expected an error for a self-referential sourceRef")
+	}
+}
+
+func TestApplyTimeMutations_CycleIsRejected(t *testing.T) {
+	mutationReferencing := func(kind, name string) string {
+		return "" +
+			"- sourceRef:\n" +
+			"    apiVersion: v1\n" +
+			"    kind: " + kind + "\n" +
+			"    name: " + name + "\n" +
+			"    namespace: default\n" +
+			"  sourcePath: $.spec.value\n" +
+			"  targetPath: $.spec.value\n"
+	}
+
+	configA := Configuration{
+		ApiVersion:   "v1",
+		Kind:         "ConfigMap",
+		MetadataName: "a",
+		Annotations:  map[string]interface{}{ApplyTimeMutationAnnotation: mutationReferencing("ConfigMap", "b")},
+		Payload:      []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"a","namespace":"default"},"spec":{"value":"a"}}`),
+	}
+	configB := Configuration{
+		ApiVersion:   "v1",
+		Kind:         "ConfigMap",
+		MetadataName: "b",
+		Annotations:  map[string]interface{}{ApplyTimeMutationAnnotation: mutationReferencing("ConfigMap", "a")},
+		Payload:      []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"b","namespace":"default"},"spec":{"value":"b"}}`),
+	}
+
+	_, err := ApplyTimeMutations([]Configuration{configA, configB})
+	if err == nil {
+		t.Fatal("expected an error for a cyclic apply-time-mutation reference")
+	}
+	if !strings.Contains(err.Error(), "cyclic") {
+		t.Errorf("error = %q, want it to mention the cycle", err.Error())
+	}
+}
+
+func TestApplyTimeMutations_MissingSourceIsReported(t *testing.T) {
+	target := Configuration{
+		ApiVersion:   "apps/v1",
+		Kind:         "Deployment",
+		MetadataName: "my-deploy",
+		Annotations: map[string]interface{}{
+			ApplyTimeMutationAnnotation: "" +
+				"- sourceRef:\n" +
+				"    apiVersion: v1\n" +
+				"    kind: Service\n" +
+				"    name: does-not-exist\n" +
+				"    namespace: default\n" +
+				"  sourcePath: $.spec.clusterIP\n" +
+				"  targetPath: $.spec.value\n",
+		},
+		Payload: []byte(`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"my-deploy","namespace":"default"},"spec":{"value":"x"}}`),
+	}
+
+	_, err := ApplyTimeMutations([]Configuration{target})
+	if err == nil {
+		t.Fatal("expected an error for a missing sourceRef")
+	}
+}
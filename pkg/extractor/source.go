@@ -0,0 +1,269 @@
+package extractor
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Source abstracts where the bytes fed into the extraction pipeline come
+// from. It's implemented by local files, stdin, http(s) URLs, and
+// tar/tar.gz archives, so ExtractConfigurationsFromSource doesn't need to
+// care which one it was handed.
+type Source interface {
+	// Name identifies the source for error reporting: a path, "stdin", or a URL.
+	Name() string
+}
+
+// SingleSource is a Source that yields exactly one YAML stream.
+type SingleSource interface {
+	Source
+	Open() (io.ReadCloser, error)
+}
+
+// ArchiveSource is a Source that expands into several named YAML streams,
+// one per entry, each becoming its own FileConfigurations.
+type ArchiveSource interface {
+	Source
+	Entries() ([]SingleSource, error)
+}
+
+// ExtractConfigurationsFromSource runs the extraction pipeline against src,
+// preserving today's per-file InvalidFile reporting granularity: an
+// ArchiveSource yields one FileConfigurations (or InvalidFile) per entry,
+// while any other Source yields exactly one.
+func ExtractConfigurationsFromSource(src Source) ([]FileConfigurations, []InvalidFile, error) {
+	if archive, ok := src.(ArchiveSource); ok {
+		entries, err := archive.Entries()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var fileConfigurations []FileConfigurations
+		var invalidFiles []InvalidFile
+		for _, entry := range entries {
+			configurations, invalidFile := extractConfigurationsFromSingleSource(entry)
+			if invalidFile != nil {
+				invalidFiles = append(invalidFiles, *invalidFile)
+				continue
+			}
+			fileConfigurations = append(fileConfigurations, FileConfigurations{FileName: entry.Name(), Configurations: *configurations})
+		}
+
+		return fileConfigurations, invalidFiles, nil
+	}
+
+	single, ok := src.(SingleSource)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported source %s", src.Name())
+	}
+
+	configurations, invalidFile := extractConfigurationsFromSingleSource(single)
+	if invalidFile != nil {
+		return nil, []InvalidFile{*invalidFile}, nil
+	}
+
+	return []FileConfigurations{{FileName: single.Name(), Configurations: *configurations}}, nil, nil
+}
+
+func extractConfigurationsFromSingleSource(src SingleSource) (*[]Configuration, *InvalidFile) {
+	reader, err := src.Open()
+	if err != nil {
+		return nil, &InvalidFile{Path: src.Name(), ValidationErrors: []error{&InvalidYamlError{ErrorMessage: err.Error()}}}
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, &InvalidFile{Path: src.Name(), ValidationErrors: []error{&InvalidYamlError{ErrorMessage: err.Error()}}}
+	}
+
+	configurations, err := ParseContent(src.Name(), string(content))
+	if err != nil {
+		return nil, &InvalidFile{Path: src.Name(), ValidationErrors: []error{err}}
+	}
+
+	mutatedConfigurations, err := ApplyTimeMutations(*configurations)
+	if err != nil {
+		return nil, &InvalidFile{Path: src.Name(), ValidationErrors: []error{err}}
+	}
+
+	return &mutatedConfigurations, nil
+}
+
+// fileSource reads a single local file already resolved to an absolute path.
+type fileSource struct {
+	path string
+}
+
+func NewFileSource(path string) Source {
+	return &fileSource{path: path}
+}
+
+func (s *fileSource) Name() string { return s.path }
+
+func (s *fileSource) Open() (io.ReadCloser, error) {
+	return os.Open(s.path)
+}
+
+// stdinSource reads a single YAML stream piped in on stdin. It is only used
+// when built with NewStdinSource; no path argument, "-" included, maps to it.
+type stdinSource struct{}
+
+func NewStdinSource() Source {
+	return stdinSource{}
+}
+
+func (stdinSource) Name() string { return "stdin" }
+
+func (stdinSource) Open() (io.ReadCloser, error) {
+	return io.NopCloser(os.Stdin), nil
+}
+
+type httpCacheEntry struct {
+	etag string
+	body []byte
+}
+
+// httpSource fetches a single YAML stream from an http(s) URL. A successful
+// response is cached on the instance by ETag so repeated scans through the
+// same httpSource send a conditional request and reuse the cached body on a
+// 304. The cache is per-instance rather than shared across all URLs/clients
+// process-wide, since two httpSources can point at the same URL with
+// different clients (e.g. different credentials) and must not see each
+// other's cached bodies.
+type httpSource struct {
+	url    string
+	client *http.Client
+
+	cacheMu sync.Mutex
+	cache   *httpCacheEntry
+}
+
+func NewHTTPSource(url string, client *http.Client) Source {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpSource{url: url, client: client}
+}
+
+func (s *httpSource) Name() string { return s.url }
+
+func (s *httpSource) Open() (io.ReadCloser, error) {
+	request, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cacheMu.Lock()
+	cached := s.cache
+	s.cacheMu.Unlock()
+	if cached != nil && cached.etag != "" {
+		request.Header.Set("If-None-Match", cached.etag)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotModified && cached != nil {
+		return io.NopCloser(bytes.NewReader(cached.body)), nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: unexpected status %s", s.url, response.Status)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if etag := response.Header.Get("ETag"); etag != "" {
+		s.cacheMu.Lock()
+		s.cache = &httpCacheEntry{etag: etag, body: body}
+		s.cacheMu.Unlock()
+	}
+
+	return io.NopCloser(bytes.NewReader(body)), nil
+}
+
+// archiveSource walks a .tar or .tar.gz file, treating each YAML entry
+// inside it as its own SingleSource.
+type archiveSource struct {
+	path string
+}
+
+func NewArchiveSource(path string) Source {
+	return &archiveSource{path: path}
+}
+
+func (s *archiveSource) Name() string { return s.path }
+
+func (s *archiveSource) Entries() ([]SingleSource, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(s.path, ".gz") || strings.HasSuffix(s.path, ".tgz") {
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+	}
+
+	tarReader := tar.NewReader(reader)
+
+	var entries []SingleSource
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg || !isYamlFile(header.Name) {
+			continue
+		}
+
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &archiveEntrySource{name: s.path + ":" + header.Name, content: content})
+	}
+
+	return entries, nil
+}
+
+func isYamlFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// archiveEntrySource hands back one archive member's already-read bytes.
+type archiveEntrySource struct {
+	name    string
+	content []byte
+}
+
+func (s *archiveEntrySource) Name() string { return s.name }
+
+func (s *archiveEntrySource) Open() (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.content)), nil
+}
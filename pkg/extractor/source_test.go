@@ -0,0 +1,111 @@
+package extractor
+
+import (
+	"archive/tar"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractConfigurationsFromSource_FileSource(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "manifest.yaml")
+	if err := os.WriteFile(path, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: from-file\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fileConfigurations, invalidFiles, err := ExtractConfigurationsFromSource(NewFileSource(path))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(invalidFiles) != 0 {
+		t.Fatalf("unexpected invalid files: %v", invalidFiles)
+	}
+	if len(fileConfigurations) != 1 || len(fileConfigurations[0].Configurations) != 1 {
+		t.Fatalf("got %+v, want exactly one file with one configuration", fileConfigurations)
+	}
+	if fileConfigurations[0].Configurations[0].MetadataName != "from-file" {
+		t.Errorf("MetadataName = %q, want from-file", fileConfigurations[0].Configurations[0].MetadataName)
+	}
+}
+
+func TestExtractConfigurationsFromSource_ArchiveSourceYieldsOneEntryPerFile(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "manifests.tar")
+
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+	writeEntry := func(name, content string) {
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Size: int64(len(content)), Mode: 0o644}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeEntry("configmap.yaml", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cm-a\n")
+	writeEntry("service.yaml", "apiVersion: v1\nkind: Service\nmetadata:\n  name: svc-a\n")
+	writeEntry("README.md", "not a manifest")
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fileConfigurations, invalidFiles, err := ExtractConfigurationsFromSource(NewArchiveSource(archivePath))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(invalidFiles) != 0 {
+		t.Fatalf("unexpected invalid files: %v", invalidFiles)
+	}
+	if len(fileConfigurations) != 2 {
+		t.Fatalf("got %d FileConfigurations, want 2 (non-YAML entries skipped)", len(fileConfigurations))
+	}
+}
+
+func TestHTTPSource_UsesETagCacheOn304(t *testing.T) {
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: from-http\n"))
+	}))
+	defer server.Close()
+
+	src := NewHTTPSource(server.URL, server.Client())
+
+	fileConfigurations, invalidFiles, err := ExtractConfigurationsFromSource(src)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(invalidFiles) != 0 {
+		t.Fatalf("unexpected invalid files: %v", invalidFiles)
+	}
+	if len(fileConfigurations) != 1 || fileConfigurations[0].Configurations[0].MetadataName != "from-http" {
+		t.Fatalf("got %+v, want one configuration named from-http", fileConfigurations)
+	}
+
+	// A second fetch should send the cached ETag and reuse the cached body on 304.
+	fileConfigurations, invalidFiles, err = ExtractConfigurationsFromSource(src)
+	if err != nil {
+		t.Fatalf("unexpected error on second fetch: %v", err)
+	}
+	if len(invalidFiles) != 0 {
+		t.Fatalf("unexpected invalid files on second fetch: %v", invalidFiles)
+	}
+	if len(fileConfigurations) != 1 || fileConfigurations[0].Configurations[0].MetadataName != "from-http" {
+		t.Fatalf("got %+v on second fetch, want the cached configuration", fileConfigurations)
+	}
+	if requestCount != 2 {
+		t.Errorf("requestCount = %d, want 2 (one miss, one conditional hit)", requestCount)
+	}
+}